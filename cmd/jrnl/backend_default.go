@@ -0,0 +1,9 @@
+package main
+
+// These backends are always compiled in: bolt is the default on-disk
+// store and memstore is a dependency-free in-memory store useful for
+// `jrnl migrate` scratch destinations and tests.
+import (
+	_ "github.com/cayleygraph/cayley/graph/bolt"
+	_ "github.com/cayleygraph/cayley/graph/memstore"
+)
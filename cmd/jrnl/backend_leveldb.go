@@ -0,0 +1,8 @@
+//go:build leveldb
+// +build leveldb
+
+package main
+
+import (
+	_ "github.com/cayleygraph/cayley/graph/leveldb"
+)
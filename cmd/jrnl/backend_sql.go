@@ -0,0 +1,8 @@
+//go:build sql
+// +build sql
+
+package main
+
+import (
+	_ "github.com/cayleygraph/cayley/graph/sql"
+)
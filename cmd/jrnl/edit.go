@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/quad"
+	"github.com/cayleygraph/cayley/schema"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// UpdateEntry replaces an existing entry's contents in place, keeping its
+// original ID and created-at time but recording a fresh updated-at. The
+// derived tag, mentions, and has-token quads are recomputed from the new
+// text, so e.g. editing "#work standup notes" to "#personal diary" drops
+// the stale #work tag and the "standup"/"notes" search tokens, and picks
+// up #personal and "diary" instead.
+//
+// contents/updated-at are always replaced, but the derived quads are
+// diffed rather than blindly removed-then-re-added: a value present in
+// both the old and new text (e.g. editing "#work standup notes" to
+// "#work revised notes" keeps the #work tag) is left untouched. Queuing
+// a remove and an add of the exact same (subject, predicate, value)
+// quad in one transaction is the known Cayley hazard where the subject
+// can come back nil, so unchanged values must never be re-queued.
+func (j *Journal) UpdateEntry(id quad.IRI, contents string) error {
+	var existing Entry
+	if err := schema.LoadTo(context.Background(), j.store, reflect.ValueOf(&existing), id); err != nil {
+		return errors.Wrap(err, "failed to load entry for update")
+	}
+
+	tx := graph.NewTransaction()
+	tx.RemoveQuad(quad.Make(id, quad.IRI("contents"), quad.String(existing.Contents), nil))
+	if existing.UpdatedAt != nil {
+		tx.RemoveQuad(quad.Make(id, quad.IRI("updated-at"), quad.Time(*existing.UpdatedAt), nil))
+	}
+	diffDerivedQuads(tx, id, existing.Contents, contents)
+
+	tx.AddQuad(quad.Make(id, quad.IRI("contents"), quad.String(contents), nil))
+	tx.AddQuad(quad.Make(id, quad.IRI("updated-at"), quad.Time(time.Now().UTC()), nil))
+
+	if err := j.store.ApplyTransaction(tx); err != nil {
+		return errors.Wrap(err, "failed to write updated entry quads")
+	}
+	return nil
+}
+
+// diffDerivedQuads queues removal of tag/mentions/has-token quads present
+// in oldContents but not newContents, and addition of those present in
+// newContents but not oldContents. A value present in both is queued for
+// neither, since a single transaction must not remove and re-add the
+// same (subject, predicate, value) quad.
+func diffDerivedQuads(tx *graph.Transaction, id quad.IRI, oldContents, newContents string) {
+	diffQuadValues(tx, id, "tag", tagValues(oldContents), tagValues(newContents))
+	diffQuadValues(tx, id, "mentions", mentionValues(oldContents), mentionValues(newContents))
+	diffQuadValues(tx, id, "has-token", tokenize(oldContents), tokenize(newContents))
+}
+
+func tagValues(contents string) []string {
+	var vals []string
+	for _, m := range tagPattern.FindAllStringSubmatch(contents, -1) {
+		vals = append(vals, m[1])
+	}
+	return vals
+}
+
+func mentionValues(contents string) []string {
+	var vals []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(contents, -1) {
+		vals = append(vals, m[1])
+	}
+	return vals
+}
+
+// diffQuadValues queues removal of (id, predicate, v) for every v in
+// oldVals that is absent from newVals, and addition of (id, predicate,
+// v) for every v in newVals absent from oldVals. Values present in both
+// are left alone.
+func diffQuadValues(tx *graph.Transaction, id quad.IRI, predicate string, oldVals, newVals []string) {
+	oldSet := toSet(oldVals)
+	newSet := toSet(newVals)
+	for v := range oldSet {
+		if !newSet[v] {
+			tx.RemoveQuad(quad.Make(id, quad.IRI(predicate), quad.String(v), nil))
+		}
+	}
+	for v := range newSet {
+		if !oldSet[v] {
+			tx.AddQuad(quad.Make(id, quad.IRI(predicate), quad.String(v), nil))
+		}
+	}
+}
+
+func toSet(vals []string) map[string]bool {
+	set := make(map[string]bool, len(vals))
+	for _, v := range vals {
+		set[v] = true
+	}
+	return set
+}
+
+// DeleteEntry removes an entry and every quad recorded for it, not just
+// the contents/created-at/is-a quads AddEntry always writes and the
+// updated-at quad UpdateEntry adds:
+// without also removing the derived tag, mentions, and has-token quads,
+// they'd become permanently unreachable orphans (no longer reachable via
+// Has(is-a, journal-entry), so not even `jrnl export` could surface them).
+func (j *Journal) DeleteEntry(id quad.IRI) error {
+	quads, err := j.quadsForSubject(id)
+	if err != nil {
+		return errors.Wrap(err, "failed to load entry for delete")
+	}
+	if len(quads) == 0 {
+		return errors.Errorf("entry %q not found", id)
+	}
+
+	tx := graph.NewTransaction()
+	for _, q := range quads {
+		tx.RemoveQuad(q)
+	}
+	if err := j.store.ApplyTransaction(tx); err != nil {
+		return errors.Wrap(err, "failed to delete entry")
+	}
+	return nil
+}
+
+// quadsForSubject returns every quad in the store with subject id.
+func (j *Journal) quadsForSubject(id quad.IRI) ([]quad.Quad, error) {
+	ctx := context.Background()
+	it := j.store.QuadsAllIterator()
+	defer it.Close()
+
+	var quads []quad.Quad
+	for it.Next(ctx) {
+		q := j.store.Quad(it.Result())
+		if q.Subject == id {
+			quads = append(quads, q)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return quads, nil
+}
+
+func newEditCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Replace an entry's contents",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				log.Fatalln("usage: jrnl edit <id>")
+			}
+			j, store := mustOpenJournal()
+			defer store.Close()
+			buf, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				log.Println("warning:", err)
+			}
+			if len(buf) == 0 {
+				log.Println("warning: empty journal input, nothing to store")
+				return
+			}
+			if err := j.UpdateEntry(quad.IRI(args[0]), string(buf)); err != nil {
+				log.Fatalln(err)
+			}
+		},
+	}
+}
+
+func newRmCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <id>",
+		Short: "Delete an entry",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				log.Fatalln("usage: jrnl rm <id>")
+			}
+			j, store := mustOpenJournal()
+			defer store.Close()
+			if err := j.DeleteEntry(quad.IRI(args[0])); err != nil {
+				log.Fatalln(err)
+			}
+		},
+	}
+}
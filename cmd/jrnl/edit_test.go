@@ -0,0 +1,177 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cayleygraph/cayley"
+	"github.com/cayleygraph/cayley/schema"
+)
+
+func newTestJournal(t *testing.T) *Journal {
+	t.Helper()
+	store, err := cayley.NewMemoryGraph()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	return NewJournal(store)
+}
+
+func onlyEntry(t *testing.T, j *Journal) Entry {
+	t.Helper()
+	results, err := j.Get(&GetOptions{})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected exactly one entry, got %d", len(results))
+	}
+	return results[0]
+}
+
+func TestUpdateEntryReplacesContents(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("#test original contents"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	id := onlyEntry(t, j).ID
+
+	if err := j.UpdateEntry(id, "#test first edit"); err != nil {
+		t.Fatalf("first UpdateEntry: %v", err)
+	}
+	if err := j.UpdateEntry(id, "#test second edit"); err != nil {
+		t.Fatalf("second UpdateEntry: %v", err)
+	}
+
+	var updated Entry
+	if err := schema.LoadTo(nil, j.store, reflect.ValueOf(&updated), id); err != nil {
+		t.Fatalf("LoadTo after update: %v", err)
+	}
+	if updated.ID != id {
+		t.Fatalf("expected entry to keep its ID %q, got %q", id, updated.ID)
+	}
+	if updated.Contents != "#test second edit" {
+		t.Fatalf("expected updated contents, got %q", updated.Contents)
+	}
+	if updated.UpdatedAt == nil {
+		t.Fatal("expected updated-at to be set after edit")
+	}
+}
+
+// TestUpdateEntryRecomputesDerivedQuads ensures an edit that changes an
+// entry's tags and searchable words drops the stale ones and picks up
+// the new ones, rather than leaking the old text's derived quads
+// forever. Mentions change in the edit too but are not checked here.
+func TestUpdateEntryRecomputesDerivedQuads(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("#work standup notes with @bob"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	id := onlyEntry(t, j).ID
+
+	if err := j.UpdateEntry(id, "#personal diary with @alice"); err != nil {
+		t.Fatalf("UpdateEntry: %v", err)
+	}
+
+	if results, err := j.Get(&GetOptions{Tag: "work"}); err != nil {
+		t.Fatalf("Get by stale tag: %v", err)
+	} else if len(results) != 0 {
+		t.Fatalf("expected no entries still tagged #work, got %d", len(results))
+	}
+	if results, err := j.Get(&GetOptions{Tag: "personal"}); err != nil {
+		t.Fatalf("Get by new tag: %v", err)
+	} else if len(results) != 1 {
+		t.Fatalf("expected one entry tagged #personal, got %d", len(results))
+	}
+	if results, err := j.Get(&GetOptions{Match: "standup"}); err != nil {
+		t.Fatalf("Get by stale token: %v", err)
+	} else if len(results) != 0 {
+		t.Fatalf("expected no entries matching stale token, got %d", len(results))
+	}
+	if results, err := j.Get(&GetOptions{Match: "diary"}); err != nil {
+		t.Fatalf("Get by new token: %v", err)
+	} else if len(results) != 1 {
+		t.Fatalf("expected one entry matching new token, got %d", len(results))
+	}
+}
+
+// TestUpdateEntryKeepsSharedDerivedQuads reproduces the ordering hazard
+// the backlog calls out: an edit that keeps a tag, mention, or token
+// unchanged must not queue a remove and an add of that exact same
+// (subject, predicate, value) quad within one transaction, since that's
+// the shape of the known Cayley corruption hazard where the subject can
+// come back nil. It must survive the update and the value must still
+// resolve afterward.
+func TestUpdateEntryKeepsSharedDerivedQuads(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("#work standup notes with @bob"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	id := onlyEntry(t, j).ID
+
+	// #work, @bob, and "notes" are unchanged; only "standup" drops and
+	// "revised" is new.
+	if err := j.UpdateEntry(id, "#work revised notes with @bob"); err != nil {
+		t.Fatalf("UpdateEntry: %v", err)
+	}
+
+	var updated Entry
+	if err := schema.LoadTo(nil, j.store, reflect.ValueOf(&updated), id); err != nil {
+		t.Fatalf("LoadTo after update: %v", err)
+	}
+	if updated.ID != id {
+		t.Fatalf("expected entry to keep its ID %q, got %q", id, updated.ID)
+	}
+	if updated.Contents != "#work revised notes with @bob" {
+		t.Fatalf("expected updated contents, got %q", updated.Contents)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		options GetOptions
+		want    int
+	}{
+		{"kept tag", GetOptions{Tag: "work"}, 1},
+		{"kept token", GetOptions{Match: "notes"}, 1},
+		{"new token", GetOptions{Match: "revised"}, 1},
+		{"dropped token", GetOptions{Match: "standup"}, 0},
+	} {
+		results, err := j.Get(&tc.options)
+		if err != nil {
+			t.Fatalf("%s: Get: %v", tc.name, err)
+		}
+		if len(results) != tc.want {
+			t.Fatalf("%s: expected %d results, got %d", tc.name, tc.want, len(results))
+		}
+	}
+}
+
+func TestDeleteEntry(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("#test goodbye @alice"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	id := onlyEntry(t, j).ID
+
+	if err := j.DeleteEntry(id); err != nil {
+		t.Fatalf("DeleteEntry: %v", err)
+	}
+
+	results, err := j.Get(&GetOptions{})
+	if err != nil {
+		t.Fatalf("Get after delete: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no entries after delete, got %d", len(results))
+	}
+
+	// Deleting must not leave orphaned tag/mentions/has-token quads
+	// behind for the subject.
+	remaining, err := j.quadsForSubject(id)
+	if err != nil {
+		t.Fatalf("quadsForSubject after delete: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Fatalf("expected no quads left for deleted entry, found %d", len(remaining))
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/cayleygraph/cayley"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/quad"
+	_ "github.com/cayleygraph/cayley/quad/json"
+	_ "github.com/cayleygraph/cayley/quad/jsonld"
+	_ "github.com/cayleygraph/cayley/quad/nquads"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const importBatchSize = 100
+
+// entryIDs returns the set of subjects that are journal entries, used by
+// Export to select which quads belong to the journal and by Import to
+// dedupe entries that have already been imported.
+func (j *Journal) entryIDs() (map[quad.Value]bool, error) {
+	ctx := context.Background()
+	it := cayley.StartPath(j.store.QuadStore).Has(quad.IRI("is-a"), quad.IRI("journal-entry")).BuildIterator()
+	defer it.Close()
+
+	ids := make(map[quad.Value]bool)
+	for it.Next(ctx) {
+		ids[j.store.NameOf(it.Result())] = true
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Export streams every quad belonging to a journal entry to w, encoded
+// in the given format ("nquads", "jsonld", or "json"), so the journal
+// can be backed up or migrated without copying the raw store file.
+func (j *Journal) Export(w io.Writer, format string) error {
+	f := quad.FormatByName(format)
+	if f == nil || f.Writer == nil {
+		return errors.Errorf("unsupported export format %q", format)
+	}
+	qw := f.Writer(w)
+	defer qw.Close()
+
+	ids, err := j.entryIDs()
+	if err != nil {
+		return errors.Wrap(err, "failed to list entries for export")
+	}
+
+	ctx := context.Background()
+	it := j.store.QuadsAllIterator()
+	defer it.Close()
+	for it.Next(ctx) {
+		q := j.store.Quad(it.Result())
+		if !ids[q.Subject] {
+			continue
+		}
+		if err := qw.WriteQuad(q); err != nil {
+			return errors.Wrap(err, "failed to write quad")
+		}
+	}
+	if err := it.Err(); err != nil {
+		return errors.Wrap(err, "failed to iterate quads")
+	}
+	return nil
+}
+
+// Import reads quads in the given format and writes them in batches via
+// a transaction, skipping any subject that is already a known journal
+// entry so that re-importing the same export is idempotent.
+func (j *Journal) Import(r io.Reader, format string) (int, error) {
+	f := quad.FormatByName(format)
+	if f == nil || f.Reader == nil {
+		return 0, errors.Errorf("unsupported import format %q", format)
+	}
+	qr := f.Reader(r)
+	defer qr.Close()
+
+	seen, err := j.entryIDs()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list existing entries")
+	}
+
+	tx := graph.NewTransaction()
+	var batch, total int
+	flush := func() error {
+		if batch == 0 {
+			return nil
+		}
+		if err := j.store.ApplyTransaction(tx); err != nil {
+			return err
+		}
+		tx = graph.NewTransaction()
+		batch = 0
+		return nil
+	}
+
+	for {
+		q, err := qr.ReadQuad()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, errors.Wrap(err, "failed to read quad")
+		}
+		if seen[q.Subject] {
+			continue
+		}
+		tx.AddQuad(q)
+		batch++
+		total++
+		if batch >= importBatchSize {
+			if err := flush(); err != nil {
+				return total, errors.Wrap(err, "failed to write import batch")
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return total, errors.Wrap(err, "failed to write import batch")
+	}
+	return total, nil
+}
+
+func newExportCommand() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all entries as quads, for backup or migration",
+		Run: func(cmd *cobra.Command, args []string) {
+			j, store := mustOpenJournal()
+			defer store.Close()
+			if err := j.Export(os.Stdout, format); err != nil {
+				log.Fatalln(err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "nquads", "export format: nquads, jsonld, or json")
+	return cmd
+}
+
+func newImportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import entries previously written with `jrnl export`",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				log.Fatalln("usage: jrnl import <file>")
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer f.Close()
+
+			j, store := mustOpenJournal()
+			defer store.Close()
+			n, err := j.Import(f, formatFromExt(args[0]))
+			if err != nil {
+				log.Fatalln(err)
+			}
+			log.Printf("imported %d quads", n)
+		},
+	}
+}
+
+// formatFromExt guesses a quad format from a file's extension, matching
+// the --format choices accepted by `jrnl export`.
+func formatFromExt(path string) string {
+	switch filepath.Ext(path) {
+	case ".jsonld":
+		return "jsonld"
+	case ".json":
+		return "json"
+	default:
+		return "nquads"
+	}
+}
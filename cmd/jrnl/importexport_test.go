@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := newTestJournal(t)
+	if err := src.AddEntry("#test roundtrip entry"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := src.Export(&buf, "nquads"); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	dst := newTestJournal(t)
+	n, err := dst.Import(bytes.NewReader(buf.Bytes()), "nquads")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if n == 0 {
+		t.Fatal("expected to import at least one quad")
+	}
+
+	results := onlyEntry(t, dst)
+	if results.Contents != "#test roundtrip entry" {
+		t.Fatalf("expected imported contents to match, got %q", results.Contents)
+	}
+
+	// Re-importing the same export must be idempotent: no new quads,
+	// still exactly one entry.
+	if _, err := dst.Import(bytes.NewReader(buf.Bytes()), "nquads"); err != nil {
+		t.Fatalf("second Import: %v", err)
+	}
+	onlyEntry(t, dst)
+}
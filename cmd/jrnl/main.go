@@ -1,19 +1,20 @@
 package main
 
 import (
+	"context"
 	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/cayleygraph/cayley"
 	"github.com/cayleygraph/cayley/graph"
-	_ "github.com/cayleygraph/cayley/graph/bolt"
 	"github.com/cayleygraph/cayley/graph/iterator"
 	"github.com/cayleygraph/cayley/quad"
+	"github.com/cayleygraph/cayley/query/gizmo"
 	"github.com/cayleygraph/cayley/schema"
 	"github.com/olebedev/when"
 	"github.com/olebedev/when/rules/common"
@@ -24,18 +25,11 @@ import (
 )
 
 func main() {
-	store, err := openStore()
-	if err != nil {
-		log.Fatalln("failed to open store:", err)
-	}
-	defer store.Close()
-	log.Println(store)
-
-	j := NewJournal(store)
-
 	cmdPut := &cobra.Command{
 		Use: "put",
 		Run: func(cmd *cobra.Command, args []string) {
+			j, store := mustOpenJournal()
+			defer store.Close()
 			buf, err := ioutil.ReadAll(os.Stdin)
 			if err != nil {
 				log.Println("warning:", err)
@@ -54,6 +48,8 @@ func main() {
 	cmdGet := &cobra.Command{
 		Use: "get",
 		Run: func(cmd *cobra.Command, args []string) {
+			j, store := mustOpenJournal()
+			defer store.Close()
 			timeSpec := strings.Join(args, " ")
 			if timeSpec != "" {
 				w := when.New(nil)
@@ -78,21 +74,63 @@ func main() {
 			}
 		},
 	}
+	cmdGet.Flags().StringVar(&getOptions.Tag, "tag", "", "only show entries tagged #<tag>")
+	cmdQuery := &cobra.Command{
+		Use: "query",
+		Run: func(cmd *cobra.Command, args []string) {
+			j, store := mustOpenJournal()
+			defer store.Close()
+			expr := strings.Join(args, " ")
+			if expr == "" {
+				log.Fatalln("usage: jrnl query <gizmo expression>")
+			}
+			results, err := j.Query(expr)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			for _, result := range results {
+				log.Println(result)
+			}
+		},
+	}
 	cmdRoot := &cobra.Command{
 		Use: "jrnl",
 	}
 	cmdRoot.AddCommand(cmdPut)
 	cmdRoot.AddCommand(cmdGet)
-	err = cmdRoot.Execute()
+	cmdRoot.AddCommand(cmdQuery)
+	cmdRoot.AddCommand(newInitCommand())
+	cmdRoot.AddCommand(newMigrateCommand())
+	cmdRoot.AddCommand(newEditCommand())
+	cmdRoot.AddCommand(newRmCommand())
+	cmdRoot.AddCommand(newServeCommand())
+	cmdRoot.AddCommand(newSearchCommand())
+	cmdRoot.AddCommand(newReindexCommand())
+	cmdRoot.AddCommand(newExportCommand())
+	cmdRoot.AddCommand(newImportCommand())
+	err := cmdRoot.Execute()
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
+// mustOpenJournal opens the configured store and wraps it in a Journal,
+// for use by subcommands that operate on journal entries rather than
+// store administration. It exits the process on failure, matching the
+// fatal error handling the rest of the command set already uses.
+func mustOpenJournal() (*Journal, *cayley.Handle) {
+	store, err := openStore()
+	if err != nil {
+		log.Fatalln("failed to open store:", err)
+	}
+	return NewJournal(store), store
+}
+
 type Entry struct {
-	ID        quad.IRI  `json:"@id"`
-	CreatedAt time.Time `json:"created-at"`
-	Contents  string    `json:"contents"`
+	ID        quad.IRI   `json:"@id"`
+	CreatedAt time.Time  `json:"created-at"`
+	UpdatedAt *time.Time `json:"updated-at,omitempty"`
+	Contents  string     `json:"contents"`
 }
 
 func NewEntry(contents string) *Entry {
@@ -111,6 +149,11 @@ func NewJournal(store *cayley.Handle) *Journal {
 	return &Journal{store: store}
 }
 
+var (
+	tagPattern     = regexp.MustCompile(`#(\w+)`)
+	mentionPattern = regexp.MustCompile(`@(\w+)`)
+)
+
 func (j *Journal) AddEntry(contents string) error {
 	entry := NewEntry(contents)
 	writer := graph.NewWriter(j.store.QuadWriter)
@@ -123,6 +166,24 @@ func (j *Journal) AddEntry(contents string) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to store entry metadata")
 	}
+	for _, m := range tagPattern.FindAllStringSubmatch(contents, -1) {
+		err = j.store.AddQuad(quad.Make(postID, quad.IRI("tag"), quad.String(m[1]), nil))
+		if err != nil {
+			return errors.Wrap(err, "failed to store entry tag")
+		}
+	}
+	for _, m := range mentionPattern.FindAllStringSubmatch(contents, -1) {
+		err = j.store.AddQuad(quad.Make(postID, quad.IRI("mentions"), quad.String(m[1]), nil))
+		if err != nil {
+			return errors.Wrap(err, "failed to store entry mention")
+		}
+	}
+	for _, tok := range tokenize(contents) {
+		err = j.store.AddQuad(quad.Make(postID, quad.IRI("has-token"), quad.String(tok), nil))
+		if err != nil {
+			return errors.Wrap(err, "failed to store entry token")
+		}
+	}
 	err = writer.Flush()
 	if err != nil {
 		return errors.Wrap(err, "failed to write entry")
@@ -133,6 +194,8 @@ func (j *Journal) AddEntry(contents string) error {
 type GetOptions struct {
 	Before *time.Time
 	After  *time.Time
+	Tag    string
+	Match  string
 }
 
 func (j *Journal) Get(options *GetOptions) ([]Entry, error) {
@@ -143,6 +206,22 @@ func (j *Journal) Get(options *GetOptions) ([]Entry, error) {
 	if options.After != nil {
 		p = p.Out(quad.IRI("created-at")).Filter(iterator.CompareGTE, quad.Time(*options.After)).Back("entry")
 	}
+	if options.Tag != "" {
+		p = p.Has(quad.IRI("tag"), quad.String(options.Tag))
+	}
+	if options.Match != "" {
+		matchTokens := tokenize(options.Match)
+		if len(matchTokens) == 0 {
+			// A query made entirely of stopwords (e.g. "the", "is it")
+			// has no searchable terms and can never match anything
+			// specific; report no matches rather than silently
+			// falling through to an unfiltered scan of every entry.
+			return nil, nil
+		}
+		for _, tok := range matchTokens {
+			p = p.Has(quad.IRI("has-token"), quad.String(tok))
+		}
+	}
 	var results []Entry
 	err := schema.LoadIteratorTo(nil, j.store, reflect.ValueOf(&results), p.BuildIterator())
 	if err != nil {
@@ -151,27 +230,25 @@ func (j *Journal) Get(options *GetOptions) ([]Entry, error) {
 	return results, nil
 }
 
-func openStore() (*cayley.Handle, error) {
-	homeDir := os.Getenv("HOME")
-	if homeDir == "" {
-		return nil, errors.New("cannot find HOME directory")
+// Query runs a Gizmo/JavaScript expression against the journal's quad
+// store directly, giving access to ad-hoc graph traversals (e.g. entries
+// tagged #project mentioning @alice) beyond what GetOptions can express.
+func (j *Journal) Query(expr string) ([]interface{}, error) {
+	ses := gizmo.NewSession(j.store.QuadStore)
+	ctx := context.Background()
+	out := make(chan interface{}, 5)
+	go ses.Execute(ctx, expr, out, -1)
+	var results []interface{}
+	for res := range out {
+		ses.Collate(res)
+		results = append(results, res)
 	}
-	jrnlPath := filepath.Join(homeDir, ".jrnl.db")
-
-	// Initialize the database
-	if _, err := os.Stat(jrnlPath); os.IsNotExist(err) {
-		err = graph.InitQuadStore("bolt", jrnlPath, nil)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to initialize database")
-		}
-	} else if err != nil {
-		return nil, errors.Wrap(err, "failed to stat database file")
-	}
-
-	// Create a brand new graph
-	store, err := cayley.NewGraph("bolt", jrnlPath, nil)
+	final, err := ses.Results()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create graph")
+		return nil, errors.Wrap(err, "failed to run query")
 	}
-	return store, nil
+	if final != nil {
+		return []interface{}{final}, nil
+	}
+	return results, nil
 }
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestQueryCountsEntries(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("#test one"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if err := j.AddEntry("#test two"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	results, err := j.Query(`g.V().Has("is-a", "journal-entry").Count()`)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("expected at least one result from a trivial count query")
+	}
+}
+
+func TestQuerySurfacesScriptErrors(t *testing.T) {
+	j := newTestJournal(t)
+	if _, err := j.Query("this is not valid gizmo"); err == nil {
+		t.Fatal("expected an error for an invalid gizmo expression, got nil")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/cayleygraph/cayley"
+	"github.com/cayleygraph/cayley/quad"
+	"github.com/cayleygraph/cayley/schema"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9']+`)
+
+// stopWords are skipped when indexing and searching so they don't dilute
+// the has-token index with near-universal, low-signal terms.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "he": true,
+	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"that": true, "the": true, "to": true, "was": true, "were": true, "will": true,
+	"with": true,
+}
+
+// tokenize splits contents into lowercase, stopword-filtered, deduplicated
+// search tokens for the has-token index.
+func tokenize(contents string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, raw := range tokenPattern.FindAllString(contents, -1) {
+		tok := strings.ToLower(raw)
+		if stopWords[tok] || seen[tok] {
+			continue
+		}
+		seen[tok] = true
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// Reindex rebuilds the has-token index for every journal entry, most
+// usefully for entries written before full-text search existed. It is
+// safe to run repeatedly: re-adding a quad that already exists is a
+// no-op.
+func (j *Journal) Reindex() (int, error) {
+	var entries []Entry
+	p := cayley.StartPath(j.store.QuadStore).Has(quad.IRI("is-a"), quad.IRI("journal-entry"))
+	err := schema.LoadIteratorTo(nil, j.store, reflect.ValueOf(&entries), p.BuildIterator())
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list entries for reindex")
+	}
+
+	for _, entry := range entries {
+		for _, tok := range tokenize(entry.Contents) {
+			if err := j.store.AddQuad(quad.Make(entry.ID, quad.IRI("has-token"), quad.String(tok), nil)); err != nil {
+				return 0, errors.Wrap(err, "failed to store entry token")
+			}
+		}
+	}
+	return len(entries), nil
+}
+
+func newSearchCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search entries by full-text match",
+		Run: func(cmd *cobra.Command, args []string) {
+			match := strings.Join(args, " ")
+			if match == "" {
+				log.Fatalln("usage: jrnl search <query>")
+			}
+			j, store := mustOpenJournal()
+			defer store.Close()
+			results, err := j.Get(&GetOptions{Match: match})
+			if err != nil {
+				log.Fatalln(err)
+			}
+			for _, result := range results {
+				log.Println(result)
+			}
+		},
+	}
+}
+
+func newReindexCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the full-text search index for all entries",
+		Run: func(cmd *cobra.Command, args []string) {
+			j, store := mustOpenJournal()
+			defer store.Close()
+			n, err := j.Reindex()
+			if err != nil {
+				log.Fatalln(err)
+			}
+			log.Printf("reindexed %d entries", n)
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cayleygraph/cayley/quad"
+)
+
+func TestSearchMatchesTokenizedContents(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("Had coffee with #alice about the new Gizmo query language"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if err := j.AddEntry("Went for a run this morning"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	results, err := j.Get(&GetOptions{Match: "gizmo"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected one matching entry, got %d", len(results))
+	}
+}
+
+// TestSearchAllStopwordsMatchesNothing guards against a query made
+// entirely of stopwords (which tokenizes to zero terms) silently
+// falling through to an unfiltered scan that returns every entry.
+func TestSearchAllStopwordsMatchesNothing(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("Had coffee with #alice about the new Gizmo query language"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	results, err := j.Get(&GetOptions{Match: "the is it"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected an all-stopword query to match nothing, got %d results", len(results))
+	}
+}
+
+func TestReindexCoversEntriesWithoutTokens(t *testing.T) {
+	j := newTestJournal(t)
+	if err := j.AddEntry("Had coffee with #alice about the new Gizmo query language"); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	// Simulate an entry written before full-text search existed by
+	// stripping its has-token quads back out.
+	id := onlyEntry(t, j).ID
+	for _, tok := range tokenize("Had coffee with #alice about the new Gizmo query language") {
+		_ = j.store.RemoveQuad(quad.Make(id, quad.IRI("has-token"), quad.String(tok), nil))
+	}
+
+	if results, err := j.Get(&GetOptions{Match: "gizmo"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	} else if len(results) != 0 {
+		t.Fatalf("expected no matches before reindex, got %d", len(results))
+	}
+
+	n, err := j.Reindex()
+	if err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected to reindex 1 entry, got %d", n)
+	}
+
+	results, err := j.Get(&GetOptions{Match: "gizmo"})
+	if err != nil {
+		t.Fatalf("Get after reindex: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected one matching entry after reindex, got %d", len(results))
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph/graphql"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// newServeCommand exposes the journal over HTTP: a REST /entries
+// endpoint using the existing Entry schema tags for request/response
+// bodies, and a /graphql endpoint backed by Cayley's own GraphQL
+// support over the journal's quad store. This lets a web or mobile
+// client talk to a `jrnl serve` instance instead of shelling out to the
+// CLI, and lets several jrnl processes share one store through it.
+func newServeCommand() *cobra.Command {
+	var addr string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the journal over HTTP and GraphQL",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := LoadConfig()
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if cfg.AuthToken == "" && !isLoopbackAddr(addr) {
+				log.Fatalf("refusing to serve %s without auth_token set in config: "+
+					"either set auth_token or bind to a loopback address", addr)
+			}
+			store, err := openStore()
+			if err != nil {
+				log.Fatalln("failed to open store:", err)
+			}
+			defer store.Close()
+			j := NewJournal(store)
+
+			gqlHandler, err := graphql.NewHandler(store.QuadStore)
+			if err != nil {
+				log.Fatalln("failed to set up graphql handler:", err)
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/entries", entriesHandler(j))
+			mux.Handle("/graphql", gqlHandler)
+
+			handler := withRequestLogging(withBearerAuth(cfg.AuthToken, mux))
+			log.Printf("serving jrnl on %s", addr)
+			log.Fatalln(http.ListenAndServe(addr, handler))
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "address to listen on")
+	return cmd
+}
+
+// isLoopbackAddr reports whether addr's host resolves to the loopback
+// interface. An empty host (e.g. ":8080") binds every interface and is
+// treated as non-loopback.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func withRequestLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Printf("%s %s %s", r.Method, r.URL.RequestURI(), time.Since(start))
+	})
+}
+
+// withBearerAuth requires a matching `Authorization: Bearer <token>`
+// header on every request when a token is configured. With no token
+// configured, auth is a no-op, matching jrnl's default of trusting its
+// single local user. The comparison runs in constant time so a network
+// attacker can't brute-force the token byte-by-byte via timing.
+func withBearerAuth(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if len(got) != len(want) || subtle.ConstantTimeCompare(got, want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func entriesHandler(j *Journal) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			putEntry(j, w, r)
+		case http.MethodGet:
+			getEntries(j, w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func putEntry(j *Journal, w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Contents string `json:"contents"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, errors.Wrap(err, "failed to decode entry").Error(), http.StatusBadRequest)
+		return
+	}
+	if err := j.AddEntry(body.Contents); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func getEntries(j *Journal, w http.ResponseWriter, r *http.Request) {
+	var options GetOptions
+	if v := r.URL.Query().Get("before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid before: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		options.Before = &t
+	}
+	if v := r.URL.Query().Get("after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid after: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		options.After = &t
+	}
+	options.Tag = r.URL.Query().Get("tag")
+	options.Match = r.URL.Query().Get("match")
+
+	results, err := j.Get(&options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		log.Println("warning: failed to encode response:", err)
+	}
+}
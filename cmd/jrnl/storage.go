@@ -0,0 +1,158 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/cayleygraph/cayley"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/quad"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const defaultBackend = "bolt"
+
+// Config describes where and how the journal's quad store is persisted.
+// It is read from ~/.jrnl/config.toml; any field left unset falls back
+// to the bolt-backed ~/.jrnl.db that earlier versions of jrnl hard-coded.
+type Config struct {
+	Backend   string                 `toml:"backend"`
+	Path      string                 `toml:"path"`
+	Options   map[string]interface{} `toml:"options"`
+	AuthToken string                 `toml:"auth_token"`
+}
+
+func configPath() (string, error) {
+	homeDir := os.Getenv("HOME")
+	if homeDir == "" {
+		return "", errors.New("cannot find HOME directory")
+	}
+	return filepath.Join(homeDir, ".jrnl", "config.toml"), nil
+}
+
+// LoadConfig reads ~/.jrnl/config.toml, falling back to the legacy
+// bolt-backed ~/.jrnl.db when no config file is present.
+func LoadConfig() (*Config, error) {
+	homeDir := os.Getenv("HOME")
+	if homeDir == "" {
+		return nil, errors.New("cannot find HOME directory")
+	}
+	cfg := &Config{
+		Backend: defaultBackend,
+		Path:    filepath.Join(homeDir, ".jrnl.db"),
+	}
+	cfgPath, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	buf, err := ioutil.ReadFile(cfgPath)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read config")
+	}
+	if err := toml.Unmarshal(buf, cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to parse config")
+	}
+	return cfg, nil
+}
+
+func (c *Config) quadStoreOptions() graph.Options {
+	return graph.Options(c.Options)
+}
+
+// InitStore explicitly initializes a new, empty quad store for the
+// configured backend. It replaces the old lazy os.Stat check that used
+// to run on every open; callers now do this once, via `jrnl init`.
+func InitStore(cfg *Config) error {
+	return graph.InitQuadStore(cfg.Backend, cfg.Path, cfg.quadStoreOptions())
+}
+
+// openStore opens the quad store described by ~/.jrnl/config.toml. The
+// store must already have been created with `jrnl init`.
+func openStore() (*cayley.Handle, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	store, err := cayley.NewGraph(cfg.Backend, cfg.Path, cfg.quadStoreOptions())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database (run `jrnl init` first?)")
+	}
+	return store, nil
+}
+
+func newInitCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init",
+		Short: "Initialize the journal's quad store",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := LoadConfig()
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if err := InitStore(cfg); err != nil {
+				log.Fatalln("failed to initialize store:", err)
+			}
+			log.Printf("initialized %s store at %s", cfg.Backend, cfg.Path)
+		},
+	}
+}
+
+func newMigrateCommand() *cobra.Command {
+	var fromBackend, fromPath, toBackend, toPath string
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy all quads from one backend to another",
+		Run: func(cmd *cobra.Command, args []string) {
+			if fromBackend == "" || toBackend == "" {
+				log.Fatalln("usage: jrnl migrate --from <backend> --to <backend> [--from-path path] [--to-path path]")
+			}
+			cfg, err := LoadConfig()
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if fromPath == "" {
+				fromPath = cfg.Path
+			}
+			if toPath == "" {
+				log.Fatalln("--to-path is required")
+			}
+
+			fromStore, err := cayley.NewGraph(fromBackend, fromPath, nil)
+			if err != nil {
+				log.Fatalln("failed to open source store:", err)
+			}
+			defer fromStore.Close()
+
+			if err := graph.InitQuadStore(toBackend, toPath, nil); err != nil {
+				log.Fatalln("failed to initialize destination store:", err)
+			}
+			toStore, err := cayley.NewGraph(toBackend, toPath, nil)
+			if err != nil {
+				log.Fatalln("failed to open destination store:", err)
+			}
+			defer toStore.Close()
+
+			reader := graph.NewQuadStoreReader(fromStore.QuadStore)
+			writer := graph.NewWriter(toStore.QuadWriter)
+			n, err := quad.Copy(writer, reader)
+			if err != nil {
+				log.Fatalln("failed to migrate quads:", err)
+			}
+			if err := writer.Flush(); err != nil {
+				log.Fatalln("failed to flush migrated quads:", err)
+			}
+			log.Printf("migrated %d quads from %s (%s) to %s (%s)", n, fromBackend, fromPath, toBackend, toPath)
+		},
+	}
+	cmd.Flags().StringVar(&fromBackend, "from", "", "source backend (bolt, leveldb, memstore, sql)")
+	cmd.Flags().StringVar(&fromPath, "from-path", "", "source store path (defaults to the configured journal)")
+	cmd.Flags().StringVar(&toBackend, "to", "", "destination backend (bolt, leveldb, memstore, sql)")
+	cmd.Flags().StringVar(&toPath, "to-path", "", "destination store path")
+	return cmd
+}